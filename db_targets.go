@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	couchdb "github.com/go-kivik/couchdb/v3" // The CouchDB driver
+	kivik "github.com/go-kivik/kivik/v3"
+	"gopkg.in/ini.v1"
+)
+
+// dbTarget is one CouchDB instance the inspector writes item-version docs
+// to. Multiple targets let one inspector run fan out to e.g. staging and
+// prod, or per-datacenter federated CouchDBs.
+type dbTarget struct {
+	name string
+	db   kivik.DB
+}
+
+// targetPassword resolves a target's password: the config key wins, and
+// falling back to $INSPECTOR_COUCHDB_PASSWORD (or, for a named target,
+// $INSPECTOR_COUCHDB_PASSWORD_<NAME>) keeps it out of the config file.
+func targetPassword(section *ini.Section, name string) string {
+	if pw := section.Key("password").String(); pw != "" {
+		return pw
+	}
+	envName := "INSPECTOR_COUCHDB_PASSWORD"
+	if name != "default" {
+		envName = envName + "_" + strings.ToUpper(name)
+	}
+	return os.Getenv(envName)
+}
+
+func openDBTarget(name string, section *ini.Section) dbTarget {
+	client, err := kivik.New("couch", section.Key("uri").String())
+	if err != nil {
+		panic(err)
+	}
+	user := section.Key("user").String()
+	client.Authenticate(context.TODO(), couchdb.BasicAuth(user, targetPassword(section, name)))
+	db := client.DB(context.TODO(), section.Key("db").String())
+	return dbTarget{name: name, db: *db}
+}
+
+// sourceConfig is the CouchDB instance the inspector reads service items
+// from (the "service_type" view), kept separate from the dbTarget(s) it
+// writes its reports to since they need not be the same cluster.
+type sourceConfig struct {
+	uri      string
+	db       string
+	user     string
+	password string
+}
+
+// loadSourceConfig reads the [inspector_source] section. Like the write
+// targets, its password falls back to $INSPECTOR_COUCHDB_PASSWORD_SOURCE
+// when not set in the config. If [inspector_source] isn't configured at
+// all, it falls back to the "default" [couchdb] write target, so a config
+// with only a single [couchdb] section keeps working unchanged.
+func loadSourceConfig(cfg *ini.File) sourceConfig {
+	section := cfg.Section("inspector_source")
+	name := "source"
+	if len(section.Keys()) == 0 {
+		section = cfg.Section("couchdb")
+		name = "default"
+	}
+	return sourceConfig{
+		uri:      strings.TrimSuffix(section.Key("uri").String(), "/"),
+		db:       section.Key("db").String(),
+		user:     section.Key("user").String(),
+		password: targetPassword(section, name),
+	}
+}
+
+// loadDBTargets builds one dbTarget per CouchDB section in the config: the
+// plain [couchdb] section is the "default" target, and every
+// [couchdb "name"] section adds another. ini.v1 doesn't parse quoted
+// subsection names (its parser only splits the unquoted "couchdb.name"
+// form), so a [couchdb "name"] section loads with its quotes and space
+// intact as the literal section name "couchdb \"name\""; quotedCouchdbName
+// parses that literal form directly. The unquoted "couchdb.name" form is
+// also accepted, for configs that spell it that way instead.
+func loadDBTargets(cfg *ini.File) []dbTarget {
+	var targets []dbTarget
+	for _, section := range cfg.Sections() {
+		name := section.Name()
+		switch {
+		case name == "couchdb":
+			targets = append(targets, openDBTarget("default", section))
+		case strings.HasPrefix(name, "couchdb."):
+			targets = append(targets, openDBTarget(strings.TrimPrefix(name, "couchdb."), section))
+		default:
+			if subName, ok := quotedCouchdbName(name); ok {
+				targets = append(targets, openDBTarget(subName, section))
+			}
+		}
+	}
+	return targets
+}
+
+// quotedCouchdbName extracts name from a section literally named
+// `couchdb "name"` (as produced by ini.v1 for a [couchdb "name"] header).
+func quotedCouchdbName(sectionName string) (string, bool) {
+	const prefix = `couchdb "`
+	if !strings.HasPrefix(sectionName, prefix) || !strings.HasSuffix(sectionName, `"`) {
+		return "", false
+	}
+	name := strings.TrimSuffix(strings.TrimPrefix(sectionName, prefix), `"`)
+	if name == "" {
+		return "", false
+	}
+	return name, true
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	kivik "github.com/go-kivik/kivik/v3"
+)
+
+// itemVersionChange is one item whose Version differs between runs.
+type itemVersionChange struct {
+	Domain string `json:"domain"`
+	Kind   string `json:"type"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// itemDiff is what changed for one kind/hostname between the previous and
+// current scan.
+type itemDiff struct {
+	Added   []ItemVersion       `json:"added,omitempty"`
+	Removed []ItemVersion       `json:"removed,omitempty"`
+	Changed []itemVersionChange `json:"changed,omitempty"`
+}
+
+func (d itemDiff) hasChanges() bool {
+	return len(d.Added) > 0 || len(d.Removed) > 0 || len(d.Changed) > 0
+}
+
+// diffItemVersions compares the items of the previous run against the
+// current one, keyed by domain+kind.
+func diffItemVersions(oldItems []ItemVersion, newItems []ItemVersion) itemDiff {
+	oldByKey := make(map[string]ItemVersion, len(oldItems))
+	for _, item := range oldItems {
+		oldByKey[item.Domain+"|"+item.Kind] = item
+	}
+
+	var diff itemDiff
+	seen := make(map[string]bool, len(newItems))
+	for _, item := range newItems {
+		key := item.Domain + "|" + item.Kind
+		seen[key] = true
+		old, existed := oldByKey[key]
+		if !existed {
+			diff.Added = append(diff.Added, item)
+			continue
+		}
+		if old.Version != item.Version {
+			diff.Changed = append(diff.Changed, itemVersionChange{
+				Domain: item.Domain,
+				Kind:   item.Kind,
+				Before: old.Version,
+				After:  item.Version,
+			})
+		}
+	}
+	for key, item := range oldByKey {
+		if !seen[key] {
+			diff.Removed = append(diff.Removed, item)
+		}
+	}
+	return diff
+}
+
+// diffNotification is the payload POSTed to [notify] webhook_url.
+type diffNotification struct {
+	Hostname string   `json:"hostname"`
+	Kind     string   `json:"kind"`
+	Diff     itemDiff `json:"diff"`
+}
+
+// notifyWebhook POSTs diff as JSON to inspector's configured webhook, if
+// any. Failures are logged, not fatal.
+func (inspector *Inspector) notifyWebhook(kind string, hostname string, diff itemDiff) {
+	if inspector.notifyWebhookURL == "" {
+		return
+	}
+	body, err := json.Marshal(diffNotification{Hostname: hostname, Kind: kind, Diff: diff})
+	if err != nil {
+		inspector.logger.Error("failed to marshal diff notification", "error", err)
+		return
+	}
+	resp, err := http.Post(inspector.notifyWebhookURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		inspector.logger.Error("failed to post diff notification", "webhook_url", inspector.notifyWebhookURL, "error", err)
+		return
+	}
+	defer resp.Body.Close()
+}
+
+// diffAgainstLastRun fetches the previously saved doc for kind/hostname
+// from the first configured target, computes what changed against the
+// items just collected, logs it at INFO and notifies the configured
+// webhook. It is a no-op unless --diff was passed.
+func (inspector *Inspector) diffAgainstLastRun(kind string, hostname string) {
+	if !inspector.diffEnabled || len(inspector.targets) == 0 {
+		return
+	}
+	id := fmt.Sprintf("%s-%s-%s", "inspector", kind, hostname)
+	target := inspector.targets[0]
+
+	var oldDoc ItemVersionDoc
+	if err := target.db.Get(context.TODO(), id).ScanDoc(&oldDoc); err != nil {
+		if kivik.StatusCode(err) != http.StatusNotFound {
+			inspector.logger.Warn("could not fetch previous doc for diff", "target", target.name, "kind", kind, "error", err)
+		}
+		return
+	}
+
+	diff := diffItemVersions(oldDoc.Items, inspector.itemsVersion)
+	if !diff.hasChanges() {
+		return
+	}
+	inspector.logger.Info("items changed since last run",
+		"kind", kind, "hostname", hostname,
+		"added", len(diff.Added), "removed", len(diff.Removed), "changed", len(diff.Changed))
+	inspector.notifyWebhook(kind, hostname, diff)
+}
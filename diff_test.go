@@ -0,0 +1,40 @@
+package main
+
+import "testing"
+
+func TestDiffItemVersions(t *testing.T) {
+	old := []ItemVersion{
+		{Domain: "a.example.com", Kind: "web", Version: "1.0"},
+		{Domain: "b.example.com", Kind: "web", Version: "2.0"},
+	}
+	current := []ItemVersion{
+		{Domain: "a.example.com", Kind: "web", Version: "1.1"},
+		{Domain: "c.example.com", Kind: "web", Version: "3.0"},
+	}
+
+	diff := diffItemVersions(old, current)
+
+	if len(diff.Added) != 1 || diff.Added[0].Domain != "c.example.com" {
+		t.Errorf("Added = %+v, want just c.example.com", diff.Added)
+	}
+	if len(diff.Removed) != 1 || diff.Removed[0].Domain != "b.example.com" {
+		t.Errorf("Removed = %+v, want just b.example.com", diff.Removed)
+	}
+	if len(diff.Changed) != 1 || diff.Changed[0].Domain != "a.example.com" ||
+		diff.Changed[0].Before != "1.0" || diff.Changed[0].After != "1.1" {
+		t.Errorf("Changed = %+v, want a.example.com 1.0 -> 1.1", diff.Changed)
+	}
+	if !diff.hasChanges() {
+		t.Error("hasChanges() = false, want true")
+	}
+}
+
+func TestDiffItemVersionsNoChanges(t *testing.T) {
+	items := []ItemVersion{{Domain: "a.example.com", Kind: "web", Version: "1.0"}}
+
+	diff := diffItemVersions(items, items)
+
+	if diff.hasChanges() {
+		t.Errorf("hasChanges() = true for identical items, want false: %+v", diff)
+	}
+}
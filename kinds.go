@@ -0,0 +1,39 @@
+package main
+
+import "context"
+
+// KindHandler describes how the inspector discovers and probes one kind of
+// service item (web, mail, dns, db, ...). ViewKey is the CouchDB
+// service_type view key the handler's items are filed under, and Probe
+// derives the version info for a single item, appending to the inspector's
+// itemsVersion/itemsNotFound slices. Probe is called with a context that
+// carries the per-probe timeout and is cancelled on SIGINT/SIGTERM.
+type KindHandler struct {
+	Name    string
+	ViewKey string
+	Probe   func(ctx context.Context, inspector *Inspector, item ItemWithSubKind)
+}
+
+var kindRegistry = map[string]*KindHandler{}
+var kindOrder []string
+
+// RegisterKind adds (or replaces) the handler for the given kind name.
+// Built-in kinds register themselves from init(). Since this is
+// package main, it cannot be imported by another binary to add further
+// kinds; doing so means adding another *.go file to this package (own
+// init() calling RegisterKind, optionally gated behind a build tag) or
+// forking it.
+func RegisterKind(name string, handler KindHandler) {
+	handler.Name = name
+	if _, exists := kindRegistry[name]; !exists {
+		kindOrder = append(kindOrder, name)
+	}
+	kindRegistry[name] = &handler
+}
+
+func init() {
+	RegisterKind("web", KindHandler{ViewKey: "web", Probe: scriptProbe})
+	RegisterKind("mail", KindHandler{ViewKey: "mail", Probe: scriptProbe})
+	RegisterKind("dns", KindHandler{ViewKey: "dns", Probe: scriptProbe})
+	RegisterKind("db", KindHandler{ViewKey: "db", Probe: scriptProbe})
+}
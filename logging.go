@@ -0,0 +1,33 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// buildLogger returns a slog.Logger writing to stderr, configured from the
+// --log-level ("debug"/"info"/"warn"/"error") and --log-format
+// ("text"/"json") flags.
+func buildLogger(level string, format string) *slog.Logger {
+	var lvl slog.Level
+	switch strings.ToLower(level) {
+	case "debug":
+		lvl = slog.LevelDebug
+	case "warn":
+		lvl = slog.LevelWarn
+	case "error":
+		lvl = slog.LevelError
+	default:
+		lvl = slog.LevelInfo
+	}
+
+	opts := &slog.HandlerOptions{Level: lvl}
+	var handler slog.Handler
+	if strings.ToLower(format) == "json" {
+		handler = slog.NewJSONHandler(os.Stderr, opts)
+	} else {
+		handler = slog.NewTextHandler(os.Stderr, opts)
+	}
+	return slog.New(handler)
+}
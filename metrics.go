@@ -0,0 +1,42 @@
+package main
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	itemVersionGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "desk_inspector_item_version",
+		Help: "Set to 1 for the version currently detected on an item; compare label sets across scrapes to see version changes.",
+	}, []string{"domain", "kind", "version"})
+
+	itemsNotFoundGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "desk_inspector_items_not_found",
+		Help: "Set to 1 while an item could not be probed on the last run.",
+	}, []string{"domain", "kind"})
+
+	probeDurationSeconds = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "desk_inspector_probe_duration_seconds",
+		Help: "Duration of one processKindItems run, per kind.",
+	}, []string{"kind"})
+
+	probeErrorsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "desk_inspector_probe_errors_total",
+		Help: "Count of panics per kind, from a whole processKindItems run or from a single item's probe.",
+	}, []string{"kind"})
+)
+
+// recordMetrics replaces the item-version/not-found gauge series for kind
+// with what the latest run collected, so a scrape reflects only the
+// current scan instead of accumulating stale label sets forever.
+func recordMetrics(kind string, itemsVersion []ItemVersion, itemsNotFound []ItemNotFound) {
+	itemVersionGauge.DeletePartialMatch(prometheus.Labels{"kind": kind})
+	for _, item := range itemsVersion {
+		itemVersionGauge.WithLabelValues(item.Domain, kind, item.Version).Set(1)
+	}
+	itemsNotFoundGauge.DeletePartialMatch(prometheus.Labels{"kind": kind})
+	for _, item := range itemsNotFound {
+		itemsNotFoundGauge.WithLabelValues(item.Domain, kind).Set(1)
+	}
+}
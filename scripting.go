@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	jsonnet "github.com/google/go-jsonnet"
+	"github.com/google/go-jsonnet/ast"
+)
+
+// scriptResult is what a `.jsonnet` probe script must evaluate to. Go
+// marshals it into an ItemVersion (or an ItemNotFound, when NotFound is
+// true) the same way the shell-script probe does for its `|`-separated
+// output.
+type scriptResult struct {
+	Version          string `json:"version"`
+	PackagesVersions string `json:"packages_versions"`
+	NotFound         bool   `json:"notFound"`
+}
+
+// newScriptVM builds a jsonnet.VM with the native funcs a probe script can
+// call to do its detection: readFile, execCapture, matchRegex, parseJSON
+// and httpGet. cmd.Dir for execCapture is workDir, same as the shell-script
+// probe's cmd.Dir; execCapture is bound by ctx, same as the shell-script
+// probe's exec.CommandContext.
+func newScriptVM(ctx context.Context, workDir string) *jsonnet.VM {
+	vm := jsonnet.MakeVM()
+
+	vm.NativeFunction(&jsonnet.NativeFunction{
+		Name:   "readFile",
+		Params: []ast.Identifier{"path"},
+		Func: func(args []interface{}) (interface{}, error) {
+			path, _ := args[0].(string)
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return nil, err
+			}
+			return string(data), nil
+		},
+	})
+
+	vm.NativeFunction(&jsonnet.NativeFunction{
+		Name:   "execCapture",
+		Params: []ast.Identifier{"cmd", "args"},
+		Func: func(args []interface{}) (interface{}, error) {
+			cmdName, _ := args[0].(string)
+			var cmdArgs []string
+			if raw, ok := args[1].([]interface{}); ok {
+				for _, a := range raw {
+					cmdArgs = append(cmdArgs, fmt.Sprint(a))
+				}
+			}
+			cmd := exec.CommandContext(ctx, cmdName, cmdArgs...)
+			cmd.Dir = workDir
+			out, err := cmd.Output()
+			if err != nil {
+				return nil, err
+			}
+			return strings.TrimSpace(string(out)), nil
+		},
+	})
+
+	vm.NativeFunction(&jsonnet.NativeFunction{
+		Name:   "matchRegex",
+		Params: []ast.Identifier{"pattern", "s"},
+		Func: func(args []interface{}) (interface{}, error) {
+			pattern, _ := args[0].(string)
+			s, _ := args[1].(string)
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, err
+			}
+			match := re.FindStringSubmatch(s)
+			result := make([]interface{}, len(match))
+			for i, m := range match {
+				result[i] = m
+			}
+			return result, nil
+		},
+	})
+
+	vm.NativeFunction(&jsonnet.NativeFunction{
+		Name:   "parseJSON",
+		Params: []ast.Identifier{"s"},
+		Func: func(args []interface{}) (interface{}, error) {
+			s, _ := args[0].(string)
+			var v interface{}
+			if err := json.Unmarshal([]byte(s), &v); err != nil {
+				return nil, err
+			}
+			return v, nil
+		},
+	})
+
+	vm.NativeFunction(&jsonnet.NativeFunction{
+		Name:   "httpGet",
+		Params: []ast.Identifier{"url"},
+		Func: func(args []interface{}) (interface{}, error) {
+			url, _ := args[0].(string)
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+			if err != nil {
+				return nil, err
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				return nil, err
+			}
+			defer resp.Body.Close()
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				return nil, err
+			}
+			return string(body), nil
+		},
+	})
+
+	return vm
+}
+
+// nativePrelude binds each of newScriptVM's native funcs to a bare local
+// name. vm.NativeFunction only ever makes a func callable as
+// std.native("name")(...); this is prepended ahead of every probe script so
+// it can call readFile/execCapture/matchRegex/parseJSON/httpGet directly, as
+// documented on newScriptVM.
+const nativePrelude = `
+local readFile = std.native("readFile");
+local execCapture = std.native("execCapture");
+local matchRegex = std.native("matchRegex");
+local parseJSON = std.native("parseJSON");
+local httpGet = std.native("httpGet");
+`
+
+// runJsonnetProbe evaluates the `.jsonnet` script at scriptPath, with
+// nativePrelude prepended so it can call the native funcs by their bare
+// name, and decodes its result into a scriptResult. workDir is available to
+// execCapture, and ctx bounds any execCapture/httpGet calls the script
+// makes.
+func runJsonnetProbe(ctx context.Context, scriptPath string, workDir string) (*scriptResult, error) {
+	vm := newScriptVM(ctx, workDir)
+	source, err := os.ReadFile(scriptPath)
+	if err != nil {
+		return nil, err
+	}
+	out, err := vm.EvaluateAnonymousSnippet(scriptPath, nativePrelude+string(source))
+	if err != nil {
+		return nil, err
+	}
+	var result scriptResult
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestRunJsonnetProbeBareNativeFuncs is a regression test for native funcs
+// being callable by their bare name (readFile here), as newScriptVM's doc
+// comment promises, rather than only via std.native("readFile").
+func TestRunJsonnetProbeBareNativeFuncs(t *testing.T) {
+	dir := t.TempDir()
+	versionFile := filepath.Join(dir, "VERSION")
+	if err := os.WriteFile(versionFile, []byte("1.2.3\n"), 0644); err != nil {
+		t.Fatalf("write fixture: %v", err)
+	}
+
+	script := `{
+  version: std.stripChars(readFile("` + versionFile + `"), "\n"),
+  packages_versions: "",
+  notFound: false,
+}
+`
+	scriptPath := filepath.Join(dir, "probe.jsonnet")
+	if err := os.WriteFile(scriptPath, []byte(script), 0644); err != nil {
+		t.Fatalf("write script: %v", err)
+	}
+
+	result, err := runJsonnetProbe(context.Background(), scriptPath, dir)
+	if err != nil {
+		t.Fatalf("runJsonnetProbe: %v", err)
+	}
+	if result.Version != "1.2.3" {
+		t.Errorf("Version = %q, want %q", result.Version, "1.2.3")
+	}
+}